@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GradingCriterion is one rubric question an assignment is graded against.
+type GradingCriterion struct {
+	ID              string `yaml:"id"`
+	Prompt          string `yaml:"prompt"`
+	AcceptanceNotes string `yaml:"acceptance_notes,omitempty"`
+}
+
+// AssignmentMatch selects which pull requests an Assignment applies to.
+// Empty fields match anything. Branch matches the PR's target (base) branch.
+// Path is matched as a prefix of the PR's head branch name, for repos that
+// use a branch-per-assignment naming convention (e.g. "hw1/student-name").
+type AssignmentMatch struct {
+	Repo   string `yaml:"repo,omitempty"`
+	Branch string `yaml:"branch,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+}
+
+// Assignment is a rubric loaded from assignments/*.yaml, describing what file
+// to grade and what questions to grade it against.
+type Assignment struct {
+	ID              string             `yaml:"id"`
+	Match           AssignmentMatch    `yaml:"match"`
+	File            string             `yaml:"file"`
+	GradingCriteria []GradingCriterion `yaml:"grading_criteria"`
+	PassThreshold   *float64           `yaml:"pass_threshold,omitempty"`
+}
+
+// fileName returns the file Assignment grades, defaulting to README.md.
+func (a *Assignment) fileName() string {
+	if a.File == "" {
+		return "README.md"
+	}
+	return a.File
+}
+
+// criterion looks up a grading criterion by ID.
+func (a *Assignment) criterion(id string) (GradingCriterion, bool) {
+	for _, c := range a.GradingCriteria {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return GradingCriterion{}, false
+}
+
+// matches reports whether the assignment applies to a pull request on
+// repoName targeting baseBranch from headBranch. Repo and branch are matched
+// exactly; an empty match field matches anything.
+func (m AssignmentMatch) matches(repoName, baseBranch, headBranch string) bool {
+	if m.Repo != "" && m.Repo != repoName {
+		return false
+	}
+	if m.Branch != "" && m.Branch != baseBranch {
+		return false
+	}
+	if m.Path != "" && !strings.HasPrefix(headBranch, m.Path) {
+		return false
+	}
+	return true
+}
+
+// specificity counts how many match fields are set, so the most specific
+// assignment can be preferred over a catch-all regardless of load order.
+func (m AssignmentMatch) specificity() int {
+	n := 0
+	if m.Repo != "" {
+		n++
+	}
+	if m.Branch != "" {
+		n++
+	}
+	if m.Path != "" {
+		n++
+	}
+	return n
+}
+
+var assignments []Assignment
+
+// loadAssignments reads every *.yaml/*.yml rubric in dir.
+func loadAssignments(dir string) ([]Assignment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading assignments directory: %w", err)
+	}
+
+	var loaded []Assignment
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading assignment %s: %w", path, err)
+		}
+
+		var a Assignment
+		if err := yaml.Unmarshal(data, &a); err != nil {
+			return nil, fmt.Errorf("error parsing assignment %s: %w", path, err)
+		}
+		if a.ID == "" {
+			return nil, fmt.Errorf("assignment %s is missing an id", path)
+		}
+		loaded = append(loaded, a)
+	}
+
+	// Sort most-specific match first, so a specific assignment (e.g.
+	// match.path: hw2/) is preferred over a catch-all (match: {}) regardless
+	// of which filename os.ReadDir happened to return first.
+	sort.SliceStable(loaded, func(i, j int) bool {
+		return loaded[i].Match.specificity() > loaded[j].Match.specificity()
+	})
+
+	return loaded, nil
+}
+
+// findAssignment returns the most specific loaded assignment whose match
+// rules apply to a pull request on repoName targeting baseBranch from
+// headBranch. loadAssignments sorts assignments most-specific first.
+func findAssignment(repoName, baseBranch, headBranch string) (*Assignment, error) {
+	for i := range assignments {
+		if assignments[i].Match.matches(repoName, baseBranch, headBranch) {
+			return &assignments[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no assignment rubric matches repo %s targeting %s", repoName, baseBranch)
+}
+
+// lineHint searches content for the rubric anchor ("> <id>.") belonging to
+// criterionID and returns its 1-based line number, or 0 if not found.
+func lineHint(content, criterionID string) int {
+	anchor := "> " + criterionID + "."
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, anchor) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// buildSystemPrompt turns an assignment's grading criteria into the OpenAI
+// system prompt, in the same "> id. prompt" format the rubric used to be
+// hardcoded in.
+func buildSystemPrompt(a *Assignment) string {
+	var b strings.Builder
+	b.WriteString("Grade the provided assignment.\n")
+	b.WriteString("Ensure that students answered all the questions as listed below, and that the answers are approximately correct.\n")
+	b.WriteString("Allow for minor variations in wording, differences in phrasing, and poor English.\n")
+	b.WriteString("Return the answer in JSON format {Qs: [{q: '<id>', g: 'correct'}, ...]}\n")
+	b.WriteString("g must be one of ['correct', 'incorrect', 'more_details_needed', 'missing']\n\n")
+	b.WriteString("Questions:\n")
+	for _, c := range a.GradingCriteria {
+		b.WriteString(fmt.Sprintf("> %s. %s\n", c.ID, c.Prompt))
+		if c.AcceptanceNotes != "" {
+			b.WriteString(c.AcceptanceNotes + "\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
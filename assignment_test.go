@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssignmentMatchMatches(t *testing.T) {
+	tests := []struct {
+		name                          string
+		match                         AssignmentMatch
+		repoName, baseBranch, headRef string
+		want                          bool
+	}{
+		{"empty match matches anything", AssignmentMatch{}, "repo", "main", "student/hw1", true},
+		{"repo match", AssignmentMatch{Repo: "repo"}, "repo", "main", "student/hw1", true},
+		{"repo mismatch", AssignmentMatch{Repo: "other"}, "repo", "main", "student/hw1", false},
+		{"branch match", AssignmentMatch{Branch: "main"}, "repo", "main", "student/hw1", true},
+		{"branch mismatch", AssignmentMatch{Branch: "develop"}, "repo", "main", "student/hw1", false},
+		{"path prefix match", AssignmentMatch{Path: "hw2/"}, "repo", "main", "hw2/student", true},
+		{"path prefix mismatch", AssignmentMatch{Path: "hw2/"}, "repo", "main", "hw1/student", false},
+		{"all fields must match", AssignmentMatch{Repo: "repo", Branch: "main", Path: "hw2/"}, "repo", "main", "hw2/student", true},
+		{"one mismatching field fails", AssignmentMatch{Repo: "repo", Branch: "main", Path: "hw2/"}, "repo", "develop", "hw2/student", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match.matches(tt.repoName, tt.baseBranch, tt.headRef); got != tt.want {
+				t.Errorf("matches(%q, %q, %q) = %v, want %v", tt.repoName, tt.baseBranch, tt.headRef, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignmentMatchSpecificity(t *testing.T) {
+	tests := []struct {
+		name  string
+		match AssignmentMatch
+		want  int
+	}{
+		{"catch-all", AssignmentMatch{}, 0},
+		{"one field", AssignmentMatch{Path: "hw2/"}, 1},
+		{"two fields", AssignmentMatch{Repo: "repo", Path: "hw2/"}, 2},
+		{"all fields", AssignmentMatch{Repo: "repo", Branch: "main", Path: "hw2/"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match.specificity(); got != tt.want {
+				t.Errorf("specificity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoadAssignmentsOrdersBySpecificity reproduces the scenario from the
+// chunk0-3 review: a specific assignment whose filename sorts before a
+// catch-all must still be preferred over the catch-all.
+func TestLoadAssignmentsOrdersBySpecificity(t *testing.T) {
+	dir := t.TempDir()
+	writeYAML(t, dir, "advanced.yaml", `
+id: advanced
+match:
+  path: hw2/
+file: README.md
+grading_criteria:
+  - id: a
+    prompt: Advanced question
+`)
+	writeYAML(t, dir, "catch-all.yaml", `
+id: catch-all
+match: {}
+file: README.md
+grading_criteria:
+  - id: a
+    prompt: Catch-all question
+`)
+
+	loaded, err := loadAssignments(dir)
+	if err != nil {
+		t.Fatalf("loadAssignments() error = %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("loadAssignments() returned %d assignments, want 2", len(loaded))
+	}
+	if loaded[0].ID != "advanced" {
+		t.Errorf("loadAssignments()[0].ID = %q, want %q (most specific first)", loaded[0].ID, "advanced")
+	}
+
+	assignments = loaded
+	defer func() { assignments = nil }()
+
+	got, err := findAssignment("repo", "main", "hw2/student")
+	if err != nil {
+		t.Fatalf("findAssignment() error = %v", err)
+	}
+	if got.ID != "advanced" {
+		t.Errorf("findAssignment() = %q, want %q", got.ID, "advanced")
+	}
+}
+
+func writeYAML(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// CheckAnnotation points at a specific line of a graded file, for forges
+// whose check/status API supports inline annotations.
+type CheckAnnotation struct {
+	Path    string
+	Line    int
+	Level   string // notice, warning, or failure
+	Title   string
+	Message string
+}
+
+// CheckRunReporter is implemented by GitSource drivers that can report
+// grading progress as a check run (or equivalent) on the PR head commit,
+// instead of or in addition to a pull request review.
+type CheckRunReporter interface {
+	// StartCheckRun creates an in_progress check run on headSHA and returns its ID.
+	StartCheckRun(ctx context.Context, owner, repo, headSHA string) (int64, error)
+	// CompleteCheckRun marks a check run completed with conclusion
+	// ("success", "failure", or "neutral") and attaches annotations.
+	CompleteCheckRun(ctx context.Context, owner, repo string, checkRunID int64, conclusion string, summary string, annotations []CheckAnnotation) error
+}
+
+const checkRunName = "DSI Autograder"
+
+func (s *GitHubSource) StartCheckRun(ctx context.Context, owner, repo, headSHA string) (int64, error) {
+	status := "in_progress"
+	run, _, err := s.client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:    checkRunName,
+		HeadSHA: headSHA,
+		Status:  &status,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error creating github check run: %w", err)
+	}
+	return run.GetID(), nil
+}
+
+func (s *GitHubSource) CompleteCheckRun(ctx context.Context, owner, repo string, checkRunID int64, conclusion string, summary string, annotations []CheckAnnotation) error {
+	status := "completed"
+	title := checkRunName
+
+	ghAnnotations := make([]*github.CheckRunAnnotation, 0, len(annotations))
+	for _, a := range annotations {
+		annotation := a
+		ghAnnotations = append(ghAnnotations, &github.CheckRunAnnotation{
+			Path:            &annotation.Path,
+			StartLine:       &annotation.Line,
+			EndLine:         &annotation.Line,
+			AnnotationLevel: &annotation.Level,
+			Title:           &annotation.Title,
+			Message:         &annotation.Message,
+		})
+	}
+
+	_, _, err := s.client.Checks.UpdateCheckRun(ctx, owner, repo, checkRunID, github.UpdateCheckRunOptions{
+		Name:       checkRunName,
+		Status:     &status,
+		Conclusion: &conclusion,
+		Output: &github.CheckRunOutput{
+			Title:       &title,
+			Summary:     &summary,
+			Annotations: ghAnnotations,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error updating github check run: %w", err)
+	}
+	return nil
+}
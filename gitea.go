@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// GiteaSource is the GitSource driver for gitea.com and self-hosted Gitea/Forgejo.
+type GiteaSource struct {
+	apiURL string
+	token  string
+	client *gitea.Client
+}
+
+// NewGiteaSource builds a GiteaSource authenticated with token, pointed at apiURL.
+func NewGiteaSource(apiURL, token string) *GiteaSource {
+	client, err := gitea.NewClient(apiURL, gitea.SetToken(token))
+	if err != nil {
+		// NewClient only fails on a malformed apiURL; defer the error to the
+		// first call so callers get it in the same place as the other drivers.
+		client = nil
+	}
+	return &GiteaSource{apiURL: apiURL, token: token, client: client}
+}
+
+func (s *GiteaSource) ensureClient() error {
+	if s.client != nil {
+		return nil
+	}
+	client, err := gitea.NewClient(s.apiURL, gitea.SetToken(s.token))
+	if err != nil {
+		return fmt.Errorf("error creating gitea client: %w", err)
+	}
+	s.client = client
+	return nil
+}
+
+func (s *GiteaSource) GetRepo(ctx context.Context, owner, repo string) error {
+	if err := s.ensureClient(); err != nil {
+		return err
+	}
+	_, _, err := s.client.GetRepo(owner, repo)
+	if err != nil {
+		return fmt.Errorf("error retrieving repo from gitea api: %w", err)
+	}
+	return nil
+}
+
+func (s *GiteaSource) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequestInfo, error) {
+	if err := s.ensureClient(); err != nil {
+		return nil, err
+	}
+	pr, _, err := s.client.GetPullRequest(owner, repo, int64(number))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pr from gitea api: %w", err)
+	}
+	return &PullRequestInfo{
+		HeadRef: pr.Head.Ref,
+		HeadSHA: pr.Head.Sha,
+		BaseRef: pr.Base.Ref,
+	}, nil
+}
+
+func (s *GiteaSource) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	if err := s.ensureClient(); err != nil {
+		return "", err
+	}
+	raw, _, err := s.client.GetContents(owner, repo, ref, path)
+	if err != nil {
+		return "", err
+	}
+	if raw.Content == nil {
+		return "", fmt.Errorf("file %s has no content at ref %s", path, ref)
+	}
+	return *raw.Content, nil
+}
+
+// CreateReview posts a pull request review. Gitea supports APPROVE, REQUEST_CHANGES
+// and COMMENT review states natively, so no fallback is needed here.
+func (s *GiteaSource) CreateReview(ctx context.Context, owner, repo string, number int, review ReviewRequest) error {
+	if err := s.ensureClient(); err != nil {
+		return err
+	}
+	_, _, err := s.client.CreatePullReview(owner, repo, int64(number), gitea.CreatePullReviewOptions{
+		Body:  review.Body,
+		State: giteaReviewState(review.Event),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating review with gitea api: %w", err)
+	}
+	return nil
+}
+
+// giteaReviewState maps our forge-agnostic PREvent onto Gitea's review state enum.
+func giteaReviewState(event PREvent) gitea.ReviewStateType {
+	switch event {
+	case APPROVE:
+		return gitea.ReviewStateApproved
+	case REQUEST_CHANGES:
+		return gitea.ReviewStateRequestChanges
+	default:
+		return gitea.ReviewStateComment
+	}
+}
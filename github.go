@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v66/github"
+	"golang.org/x/oauth2"
+)
+
+// GitHubSource is the GitSource driver for github.com and GitHub Enterprise.
+type GitHubSource struct {
+	client *github.Client
+}
+
+// NewGitHubSource builds a GitHubSource authenticated with a personal access
+// token or installation access token, pointed at apiURL (or api.github.com
+// if apiURL is empty). Requests retry on 429/5xx responses per config.Retry.
+func NewGitHubSource(apiURL, token string) (*GitHubSource, error) {
+	retryClient := &http.Client{Transport: newRetryingTransport(http.DefaultTransport, config.Retry)}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, retryClient)
+	oauthClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+
+	if apiURL == "" {
+		return &GitHubSource{client: github.NewClient(oauthClient)}, nil
+	}
+
+	client, err := github.NewEnterpriseClient(apiURL, apiURL, oauthClient)
+	if err != nil {
+		return nil, fmt.Errorf("error creating github enterprise client: %w", err)
+	}
+	return &GitHubSource{client: client}, nil
+}
+
+func (s *GitHubSource) GetRepo(ctx context.Context, owner, repo string) error {
+	_, ghStatus, err := s.client.Repositories.Get(ctx, owner, repo)
+	if err != nil || ghStatus.StatusCode != http.StatusOK {
+		return fmt.Errorf("error retrieving repo from github api: %w", err)
+	}
+	return nil
+}
+
+func (s *GitHubSource) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequestInfo, error) {
+	ghPr, ghStatus, err := s.client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil || ghStatus.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error retrieving pr from github api: %w", err)
+	}
+	return &PullRequestInfo{
+		HeadRef: ghPr.GetHead().GetRef(),
+		HeadSHA: ghPr.GetHead().GetSHA(),
+		BaseRef: ghPr.GetBase().GetRef(),
+	}, nil
+}
+
+func (s *GitHubSource) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	fileContent, _, _, err := s.client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if err != nil {
+		return "", err
+	}
+	return fileContent.GetContent()
+}
+
+// CreateReview posts a pull request review. GitHub supports APPROVE, REQUEST_CHANGES
+// and COMMENT natively, so no fallback is needed here.
+func (s *GitHubSource) CreateReview(ctx context.Context, owner, repo string, number int, review ReviewRequest) error {
+	event := string(review.Event)
+	_, _, err := s.client.PullRequests.CreateReview(ctx, owner, repo, number, &github.PullRequestReviewRequest{
+		Body:  &review.Body,
+		Event: &event,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating review with github api: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/google/go-github/v66/github"
+)
+
+// GitHubAppConfig configures GitHub App authentication, replacing the
+// ?token= personal-access-token flow for github-type repos.
+type GitHubAppConfig struct {
+	AppID          int64  `yaml:"app_id"`
+	PrivateKeyPath string `yaml:"private_key_path"`
+	WebhookSecret  string `yaml:"webhook_secret"`
+}
+
+// githubAppAuth mints and caches per-repo GitHub App installation access tokens.
+type githubAppAuth struct {
+	appsTransport *ghinstallation.AppsTransport
+	jwtClient     *github.Client
+
+	installationsMu sync.RWMutex
+	installations   map[string]*ghinstallation.Transport // keyed by "owner/repo"
+}
+
+// newGitHubAppAuth loads the app's RSA private key from privateKeyPath.
+func newGitHubAppAuth(appID int64, privateKeyPath string) (*githubAppAuth, error) {
+	appsTransport, err := ghinstallation.NewAppsTransportKeyFromFile(newRetryingTransport(http.DefaultTransport, config.Retry), appID, privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading github app private key: %w", err)
+	}
+	return &githubAppAuth{
+		appsTransport: appsTransport,
+		jwtClient:     github.NewClient(&http.Client{Transport: appsTransport}),
+		installations: make(map[string]*ghinstallation.Transport),
+	}, nil
+}
+
+// installationToken resolves the installation for owner/repo and returns a
+// short-lived installation access token. Tokens are cached per repo and only
+// reminted once ghinstallation reports the cached one has expired.
+func (a *githubAppAuth) installationToken(ctx context.Context, owner, repo string) (string, error) {
+	key := owner + "/" + repo
+
+	a.installationsMu.RLock()
+	transport, ok := a.installations[key]
+	a.installationsMu.RUnlock()
+
+	if !ok {
+		installation, _, err := a.jwtClient.Apps.FindRepositoryInstallation(ctx, owner, repo)
+		if err != nil {
+			return "", fmt.Errorf("error finding github app installation for %s: %w", key, err)
+		}
+		transport = ghinstallation.NewFromAppsTransport(a.appsTransport, installation.GetID())
+
+		a.installationsMu.Lock()
+		a.installations[key] = transport
+		a.installationsMu.Unlock()
+	}
+
+	return transport.Token(ctx)
+}
+
+var appAuth *githubAppAuth
+
+// resolveToken returns the credentials checkRepoHandler/webhookHandler should
+// authenticate with: a GitHub App installation token when the repo is a
+// github-type repo and an app is configured, otherwise fallbackToken (a
+// per-request query token, or the per-repo token from config).
+func resolveToken(ctx context.Context, repoCfg RepoConfig, userName, repoName, fallbackToken string) (string, error) {
+	isGitHub := repoCfg.Type == "" || repoCfg.Type == SourceTypeGitHub
+	if isGitHub && appAuth != nil {
+		return appAuth.installationToken(ctx, userName, repoName)
+	}
+
+	if fallbackToken != "" {
+		return fallbackToken, nil
+	}
+	if repoCfg.Token != "" {
+		return repoCfg.Token, nil
+	}
+	return "", fmt.Errorf("no credentials configured for repo %s", repoName)
+}
+
+// webhookHandler verifies the GitHub webhook signature and triggers grading
+// on pull_request opened/synchronize events, so clients no longer need to
+// call /checkrepo directly.
+func webhookHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if config.GitHubApp == nil || config.GitHubApp.WebhookSecret == "" {
+		http.Error(w, "webhook not configured", http.StatusNotImplemented)
+		return
+	}
+
+	payload, err := github.ValidatePayload(r, []byte(config.GitHubApp.WebhookSecret))
+	if err != nil {
+		http.Error(w, "invalid webhook signature", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := github.ParseWebHook(github.WebHookType(r), payload)
+	if err != nil {
+		http.Error(w, "error parsing webhook payload", http.StatusBadRequest)
+		return
+	}
+
+	prEvent, ok := event.(*github.PullRequestEvent)
+	if !ok {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch prEvent.GetAction() {
+	case "opened", "synchronize":
+	default:
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	userName := prEvent.GetRepo().GetOwner().GetLogin()
+	repoName := prEvent.GetRepo().GetName()
+	prNum := prEvent.GetNumber()
+
+	// Mirror checkRepoHandler's allowlist check: RepoConfig has no owner field,
+	// so repoName alone can't tell this repo apart from a same-named repo
+	// owned by someone else that the GitHub App also happens to be installed on.
+	if !slices.Contains(config.UserNames, userName) {
+		log.Printf("ignoring webhook for %s/%s#%d: user not allowed", userName, repoName, prNum)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	go func() {
+		if _, err := gradePullRequest(context.Background(), userName, repoName, prNum, ""); err != nil {
+			log.Printf("error grading %s/%s#%d from webhook: %v", userName, repoName, prNum, err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
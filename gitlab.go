@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+// GitLabSource is the GitSource driver for gitlab.com and self-hosted GitLab.
+type GitLabSource struct {
+	client *gitlab.Client
+}
+
+// NewGitLabSource builds a GitLabSource authenticated with a personal/project
+// access token, pointed at apiURL (or gitlab.com if apiURL is empty).
+func NewGitLabSource(apiURL, token string) (*GitLabSource, error) {
+	var opts []gitlab.ClientOptionFunc
+	if apiURL != "" {
+		opts = append(opts, gitlab.WithBaseURL(apiURL))
+	}
+	client, err := gitlab.NewClient(token, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gitlab client: %w", err)
+	}
+	return &GitLabSource{client: client}, nil
+}
+
+func (s *GitLabSource) projectPath(owner, repo string) string {
+	return owner + "/" + repo
+}
+
+func (s *GitLabSource) GetRepo(ctx context.Context, owner, repo string) error {
+	_, _, err := s.client.Projects.GetProject(s.projectPath(owner, repo), nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("error retrieving project from gitlab api: %w", err)
+	}
+	return nil
+}
+
+func (s *GitLabSource) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequestInfo, error) {
+	mr, _, err := s.client.MergeRequests.GetMergeRequest(s.projectPath(owner, repo), number, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving merge request from gitlab api: %w", err)
+	}
+	return &PullRequestInfo{
+		HeadRef: mr.SourceBranch,
+		HeadSHA: mr.SHA,
+		BaseRef: mr.TargetBranch,
+	}, nil
+}
+
+func (s *GitLabSource) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	file, _, err := s.client.RepositoryFiles.GetFile(s.projectPath(owner, repo), path, &gitlab.GetFileOptions{Ref: &ref}, gitlab.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	if file.Encoding != "base64" {
+		return file.Content, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(file.Content)
+	if err != nil {
+		return "", fmt.Errorf("error decoding gitlab file content: %w", err)
+	}
+	return string(decoded), nil
+}
+
+// CreateReview approves or posts a note on a merge request. GitLab has no
+// REQUEST_CHANGES review event, so that case falls back to un-approving and
+// leaving a comment with the grading results instead.
+func (s *GitLabSource) CreateReview(ctx context.Context, owner, repo string, number int, review ReviewRequest) error {
+	project := s.projectPath(owner, repo)
+
+	switch review.Event {
+	case APPROVE:
+		_, _, err := s.client.MergeRequestApprovals.ApproveMergeRequest(project, number, nil, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("error approving merge request on gitlab: %w", err)
+		}
+		return s.postNote(ctx, project, number, review.Body)
+	case REQUEST_CHANGES:
+		// GitLab has no request-changes review state; fall back to a comment.
+		_, _ = s.client.MergeRequestApprovals.UnapproveMergeRequest(project, number, gitlab.WithContext(ctx))
+		return s.postNote(ctx, project, number, review.Body)
+	default:
+		return s.postNote(ctx, project, number, review.Body)
+	}
+}
+
+func (s *GitLabSource) postNote(ctx context.Context, project string, number int, body string) error {
+	_, _, err := s.client.Notes.CreateMergeRequestNote(project, number, &gitlab.CreateMergeRequestNoteOptions{
+		Body: &body,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("error posting note on gitlab merge request: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PullRequestInfo holds the subset of pull/merge request fields the grader needs,
+// independent of which forge it came from.
+type PullRequestInfo struct {
+	HeadRef string
+	HeadSHA string
+	BaseRef string
+}
+
+// ReviewRequest is a forge-agnostic review to post back on a pull/merge request.
+type ReviewRequest struct {
+	Body  string
+	Event PREvent
+}
+
+// GitSource is the interface every supported git hosting backend implements.
+// It covers exactly what checkRepoHandler needs: confirming the repo and PR
+// exist, fetching a file at the PR head, and posting the grading result back.
+type GitSource interface {
+	// GetRepo confirms the repo exists and is reachable with the configured credentials.
+	GetRepo(ctx context.Context, owner, repo string) error
+	// GetPullRequest fetches the pull/merge request identified by number.
+	GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequestInfo, error)
+	// GetFileContent fetches the raw contents of path at ref.
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	// CreateReview posts review back on the pull/merge request. If the forge
+	// does not support the requested event (e.g. REQUEST_CHANGES), implementations
+	// fall back to posting a plain comment instead.
+	CreateReview(ctx context.Context, owner, repo string, number int, review ReviewRequest) error
+}
+
+// Supported GitSource driver names, as configured per-repo via RepoConfig.Type.
+const (
+	SourceTypeGitHub = "github"
+	SourceTypeGitLab = "gitlab"
+	SourceTypeGitea  = "gitea"
+)
+
+// NewGitSource builds the GitSource driver configured for repoCfg, authenticated with token.
+func NewGitSource(repoCfg RepoConfig, token string) (GitSource, error) {
+	switch repoCfg.Type {
+	case "", SourceTypeGitHub:
+		return NewGitHubSource(repoCfg.APIURL, token)
+	case SourceTypeGitLab:
+		return NewGitLabSource(repoCfg.APIURL, token)
+	case SourceTypeGitea:
+		return NewGiteaSource(repoCfg.APIURL, token), nil
+	default:
+		return nil, fmt.Errorf("unsupported git source type %q", repoCfg.Type)
+	}
+}
+
+// ParseRepoURL validates the given URL, extracts the owner and repository name,
+// and infers which git hosting backend the URL belongs to from its host.
+// Self-hosted GitLab/Gitea instances can't be inferred from the host alone, so
+// callers should prefer the Type configured for the matching RepoConfig and
+// only fall back to the inferred type when the repo isn't explicitly configured.
+func ParseRepoURL(urlStr string) (sourceType, owner, reponame string, err error) {
+	u, err := url.Parse(urlStr)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", "", "", errors.New("invalid URL")
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", "", errors.New("URL must start with http or https")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	switch host {
+	case "github.com":
+		sourceType = SourceTypeGitHub
+	case "gitlab.com":
+		sourceType = SourceTypeGitLab
+	case "gitea.com":
+		sourceType = SourceTypeGitea
+	default:
+		sourceType = SourceTypeGitHub
+	}
+
+	pathParts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(pathParts) < 2 {
+		return "", "", "", errors.New("URL must include both owner and repository name")
+	}
+
+	owner = pathParts[0]
+	reponame = strings.TrimSuffix(pathParts[1], ".git")
+
+	return sourceType, owner, reponame, nil
+}
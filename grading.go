@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+// gradedQuestion is one rubric question's final grade after majority voting
+// across config.GradingSamples independent OpenAI samples.
+type gradedQuestion struct {
+	Q         string  `json:"q"`
+	G         string  `json:"g"`
+	Agreement float64 `json:"agreement"`
+}
+
+// gradingSeed is fixed so that, together with temperature 0, samples are as
+// deterministic as the model allows.
+const gradingSeed = 42
+
+// gradePullRequest resolves the GitSource and credentials for repoName, picks
+// the matching assignment rubric, fetches its file at the pull request head,
+// grades it against the rubric with OpenAI, and posts the result back as a
+// review. It is shared by checkRepoHandler (for on-demand /checkrepo calls)
+// and webhookHandler (for GitHub App webhook events).
+// fallbackToken is used when the repo isn't authenticated via GitHub App auth
+// (e.g. GitLab/Gitea repos, or GitHub repos with no app configured).
+func gradePullRequest(ctx context.Context, userName, repoName string, prNum int, fallbackToken string) (Response, error) {
+	repoCfg, ok := findRepoConfig(repoName)
+	if !ok {
+		return Response{}, fmt.Errorf("repo %s not allowed", repoName)
+	}
+
+	token, err := resolveToken(ctx, repoCfg, userName, repoName, fallbackToken)
+	if err != nil {
+		return Response{}, err
+	}
+
+	source, err := NewGitSource(repoCfg, token)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if err := source.GetRepo(ctx, userName, repoName); err != nil {
+		return Response{}, err
+	}
+
+	pr, err := source.GetPullRequest(ctx, userName, repoName, prNum)
+	if err != nil {
+		return Response{}, err
+	}
+
+	branchName := pr.HeadRef
+	if branchName == "" {
+		return Response{}, fmt.Errorf("error getting head branch from pull request")
+	}
+
+	assignment, err := findAssignment(repoName, pr.BaseRef, pr.HeadRef)
+	if err != nil {
+		return Response{}, err
+	}
+
+	checkRunner, supportsChecks := source.(CheckRunReporter)
+	reportChecks := supportsChecks && config.postsCheckRun()
+	// Fall back to posting a review whenever the resolved source can't report
+	// check runs, regardless of ReportMode, so a report_mode: "checks" config
+	// doesn't silently drop results for non-GitHub repos.
+	postReview := config.postsReview() || !reportChecks
+	var checkRunID int64
+	if reportChecks {
+		checkRunID, err = checkRunner.StartCheckRun(ctx, userName, repoName, pr.HeadSHA)
+		if err != nil {
+			return Response{}, err
+		}
+	}
+
+	content, err := source.GetFileContent(ctx, userName, repoName, assignment.fileName(), branchName)
+	if err != nil {
+		response := Response{
+			Status:  REQUEST_CHANGES,
+			Message: fmt.Sprintf("Error retrieving %s file.", assignment.fileName()),
+		}
+		if reportChecks {
+			checkRunner.CompleteCheckRun(ctx, userName, repoName, checkRunID, "failure", response.Message, nil)
+		}
+		if postReview {
+			source.CreateReview(ctx, userName, repoName, prNum, ReviewRequest{Body: "### DSI Autograder\n" + response.Message, Event: response.Status})
+		}
+		return response, nil
+	}
+
+	response, annotations, err := gradeSubmission(ctx, assignment, content)
+	if err != nil {
+		return Response{}, err
+	}
+
+	log.Println(response)
+
+	if reportChecks {
+		conclusion := "success"
+		if response.Status != APPROVE {
+			conclusion = "failure"
+		}
+		if err := checkRunner.CompleteCheckRun(ctx, userName, repoName, checkRunID, conclusion, response.Message, annotations); err != nil {
+			return Response{}, err
+		}
+	}
+
+	if postReview {
+		if err := source.CreateReview(ctx, userName, repoName, prNum, ReviewRequest{
+			Body:  "### DSI Autograder\n" + response.Message,
+			Event: response.Status,
+		}); err != nil {
+			return Response{}, fmt.Errorf("error creating review: %w", err)
+		}
+	}
+
+	return response, nil
+}
+
+// gradeSubmission grades content against assignment's rubric. It takes a
+// majority vote across config.GradingSamples independent, temperature-0
+// OpenAI samples to reduce flakiness, turns the result into a Response with
+// a markdown table keyed by rubric question ID, and builds a check run
+// annotation for every question graded incorrect or missing, located via its
+// "> <id>." anchor. Results are cached on disk by sha256(model + system
+// prompt + content), so re-grading the same PR head SHA is free and stable.
+func gradeSubmission(ctx context.Context, assignment *Assignment, content string) (Response, []CheckAnnotation, error) {
+	systemPrompt := buildSystemPrompt(assignment)
+	key := gradingCacheKey(openai.GPT4oMini, systemPrompt, content)
+	if cached, ok := readGradingCache(config.CacheDir, key); ok {
+		return cached.Response, cached.Annotations, nil
+	}
+
+	samples := config.GradingSamples
+	if samples <= 0 {
+		samples = 1
+	}
+
+	oaConfig := openai.DefaultConfig(config.OpenAIToken)
+	oaConfig.HTTPClient = &http.Client{Transport: newRetryingTransport(http.DefaultTransport, config.Retry)}
+	oClient := openai.NewClientWithConfig(oaConfig)
+
+	var oResponse OpenAIResponse
+	schema, err := jsonschema.GenerateSchemaForType(oResponse)
+	if err != nil {
+		log.Fatalf("GenerateSchemaForType error: %v", err)
+	}
+
+	seed := gradingSeed
+	samplesByQuestion := make(map[string][]string)
+	var questionOrder []string
+	for i := 0; i < samples; i++ {
+		resp, err := oClient.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model:       openai.GPT4oMini,
+			Temperature: 0,
+			Seed:        &seed,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleSystem,
+					Content: systemPrompt,
+				},
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: content,
+				},
+			},
+			ResponseFormat: &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+				JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+					Name:   "github_assignment_review",
+					Schema: schema,
+					Strict: true,
+				},
+			},
+		})
+		if err != nil {
+			return Response{}, nil, fmt.Errorf("error creating chat completion with OpenAI API: %w", err)
+		}
+
+		var sample OpenAIResponse
+		if err := schema.Unmarshal(resp.Choices[0].Message.Content, &sample); err != nil {
+			return Response{}, nil, fmt.Errorf("error unmarshalling OpenAI response: %w", err)
+		}
+		for _, answer := range sample.Qs {
+			if _, seen := samplesByQuestion[answer.Q]; !seen {
+				questionOrder = append(questionOrder, answer.Q)
+			}
+			samplesByQuestion[answer.Q] = append(samplesByQuestion[answer.Q], answer.G)
+		}
+	}
+
+	graded := make([]gradedQuestion, 0, len(questionOrder))
+	for _, id := range questionOrder {
+		graded = append(graded, majorityGrade(id, samplesByQuestion[id]))
+	}
+
+	// format the answers as a markdown table, keyed by rubric question ID and criteria
+	var answers strings.Builder
+	answers.WriteString("| Question | Criteria | Grade | Agreement |\n")
+	answers.WriteString("| --- | --- | --- | --- |\n")
+	var correctAnswers int
+	var annotations []CheckAnnotation
+	for _, answer := range graded {
+		criterion, _ := assignment.criterion(answer.Q)
+		answers.WriteString(fmt.Sprintf("| %s | %s | %s | %.0f%% |\n", answer.Q, criterion.Prompt, answer.G, answer.Agreement*100))
+		if answer.G == "correct" {
+			correctAnswers++
+			continue
+		}
+
+		line := lineHint(content, answer.Q)
+		if line == 0 {
+			line = 1
+		}
+		annotations = append(annotations, CheckAnnotation{
+			Path:    assignment.fileName(),
+			Line:    line,
+			Level:   "failure",
+			Title:   fmt.Sprintf("Question %s: %s", answer.Q, answer.G),
+			Message: criterion.Prompt,
+		})
+	}
+
+	threshold := 1.0
+	if assignment.PassThreshold != nil {
+		threshold = *assignment.PassThreshold
+	}
+	var passFraction float64
+	if len(graded) > 0 {
+		passFraction = float64(correctAnswers) / float64(len(graded))
+	}
+
+	response := Response{Status: REQUEST_CHANGES, Message: answers.String()}
+	if passFraction >= threshold {
+		response.Status = APPROVE
+	}
+
+	if err := writeGradingCache(config.CacheDir, key, gradingCacheEntry{
+		Response:    response,
+		Annotations: annotations,
+		Graded:      graded,
+	}); err != nil {
+		log.Printf("error writing grading cache: %v", err)
+	}
+
+	return response, annotations, nil
+}
+
+// majorityGrade picks the most common grade among votes for question id,
+// breaking ties in favor of whichever grade was cast first, and reports what
+// fraction of samples agreed with the winner.
+func majorityGrade(id string, votes []string) gradedQuestion {
+	counts := make(map[string]int, len(votes))
+	var winner string
+	var winnerCount int
+	for _, g := range votes {
+		counts[g]++
+		if counts[g] > winnerCount {
+			winner = g
+			winnerCount = counts[g]
+		}
+	}
+	return gradedQuestion{Q: id, G: winner, Agreement: float64(winnerCount) / float64(len(votes))}
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestMajorityGrade(t *testing.T) {
+	tests := []struct {
+		name          string
+		votes         []string
+		wantGrade     string
+		wantAgreement float64
+	}{
+		{"unanimous", []string{"correct", "correct", "correct"}, "correct", 1},
+		{"clear majority", []string{"correct", "correct", "incorrect"}, "correct", 2.0 / 3.0},
+		{"tie breaks to first cast", []string{"incorrect", "correct", "incorrect", "correct"}, "incorrect", 0.5},
+		{"single vote", []string{"missing"}, "missing", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := majorityGrade("q1", tt.votes)
+			if got.G != tt.wantGrade {
+				t.Errorf("majorityGrade(%v).G = %q, want %q", tt.votes, got.G, tt.wantGrade)
+			}
+			if got.Agreement != tt.wantAgreement {
+				t.Errorf("majorityGrade(%v).Agreement = %v, want %v", tt.votes, got.Agreement, tt.wantAgreement)
+			}
+			if got.Q != "q1" {
+				t.Errorf("majorityGrade(%v).Q = %q, want %q", tt.votes, got.Q, "q1")
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// gradingCacheEntry is what's persisted on disk, keyed by
+// sha256(model + system prompt + submission content), so re-grading the same
+// PR head SHA doesn't re-bill OpenAI or reshuffle grades across samples.
+type gradingCacheEntry struct {
+	Response    Response          `json:"response"`
+	Annotations []CheckAnnotation `json:"annotations,omitempty"`
+	Graded      []gradedQuestion  `json:"graded"`
+}
+
+// gradingCacheKey hashes the exact inputs that determine a grading result.
+func gradingCacheKey(model, systemPrompt, content string) string {
+	sum := sha256.Sum256([]byte(model + systemPrompt + content))
+	return hex.EncodeToString(sum[:])
+}
+
+// readGradingCache returns the cached entry for key, if dir is configured and it exists.
+func readGradingCache(dir, key string) (*gradingCacheEntry, bool) {
+	if dir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var entry gradingCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// writeGradingCache persists entry under key, if dir is configured. The write
+// goes through a temp file + rename so concurrent gradings of byte-identical
+// submissions (e.g. unedited README boilerplate) can't interleave and
+// corrupt the cache file.
+func writeGradingCache(dir, key string, entry gradingCacheEntry) error {
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating grading cache directory: %w", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error marshalling grading cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating grading cache temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing grading cache temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing grading cache temp file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(dir, key+".json"))
+}
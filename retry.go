@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryConfig configures retry/backoff behavior for outbound OpenAI and
+// GitHub API calls.
+type RetryConfig struct {
+	MaxRetries       int `yaml:"max_retries,omitempty"`
+	BaseDelaySeconds int `yaml:"base_delay_seconds,omitempty"`
+}
+
+// retryingTransport retries requests that fail with 429 or 5xx, honoring the
+// Retry-After header (seconds or HTTP-date) and GitHub's X-RateLimit-Reset
+// header, backing off exponentially with jitter between attempts.
+type retryingTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+}
+
+// newRetryingTransport wraps base with retry/backoff behavior from cfg,
+// applying repo-wide defaults (3 retries, 1s base delay) when unset.
+func newRetryingTransport(base http.RoundTripper, cfg RetryConfig) *retryingTransport {
+	maxRetries := cfg.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	baseDelay := time.Duration(cfg.BaseDelaySeconds) * time.Second
+	if baseDelay <= 0 {
+		baseDelay = time.Second
+	}
+	return &retryingTransport{base: base, maxRetries: maxRetries, baseDelay: baseDelay}
+}
+
+func (t *retryingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+
+	for attempt := 0; attempt <= t.maxRetries; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				break
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req.Body = body
+		}
+
+		resp, err = t.base.RoundTrip(req)
+		if err != nil {
+			if attempt == t.maxRetries {
+				return resp, err
+			}
+			time.Sleep(t.backoff(attempt))
+			continue
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return resp, nil
+		}
+		if attempt == t.maxRetries {
+			return resp, nil
+		}
+
+		delay := retryDelay(resp, t.backoff(attempt))
+		resp.Body.Close()
+		time.Sleep(delay)
+	}
+
+	return resp, err
+}
+
+// backoff computes the exponential delay for attempt, plus jitter so that
+// concurrent requests retrying at once don't stampede in lockstep.
+func (t *retryingTransport) backoff(attempt int) time.Duration {
+	jitter := time.Duration(rand.Int63n(int64(t.baseDelay)))
+	return t.baseDelay*time.Duration(math.Pow(2, float64(attempt))) + jitter
+}
+
+// retryDelay honors Retry-After (seconds or HTTP-date) and GitHub's
+// X-RateLimit-Reset (epoch seconds) response headers, falling back to fallback.
+func retryDelay(resp *http.Response, fallback time.Duration) time.Duration {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if when, err := http.ParseTime(v); err == nil {
+			if d := time.Until(when); d > 0 {
+				return d
+			}
+		}
+	}
+	if v := resp.Header.Get("X-RateLimit-Reset"); v != "" {
+		if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+			if d := time.Until(time.Unix(epoch, 0)); d > 0 {
+				return d
+			}
+		}
+	}
+	return fallback
+}